@@ -0,0 +1,76 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config holds the completed, ready-to-run configuration for kube-controller-manager.
+package config
+
+import (
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	componentbaseconfig "k8s.io/component-base/config"
+	"k8s.io/component-base/metrics"
+	"k8s.io/kubernetes/cmd/kube-controller-manager/app/controllerset"
+)
+
+// Config is the main context object for kube-controller-manager. It is built from options.
+type Config struct {
+	// Kubeconfig is the rest config used to build every controller's clients.
+	Kubeconfig *restclient.Config
+	// LoopbackClientConfig is a rest client config using the magic loopback token, used to reach
+	// this instance's own healthz/metrics/debug endpoints. When secure serving is off (bind port 0)
+	// it is a copy of Kubeconfig instead, and so reaches the apiserver.
+	LoopbackClientConfig *restclient.Config
+
+	Client          *kubernetes.Clientset
+	SharedInformers informers.SharedInformerFactory
+
+	SecureServing *genericapiserver.SecureServingInfo
+
+	// LeaderElection controls whether Run gates starting controllers on acquiring a leader lease,
+	// and the lock's name/namespace/timing if so. Populated from the --leader-elect* flags.
+	LeaderElection componentbaseconfig.LeaderElectionConfiguration
+
+	// Registry is where this instance's controllers and subsystems (e.g. ControllerSetManager)
+	// register their metrics. KubeControllerManagerOptions.Config sets a fresh registry, and Run
+	// falls back to a fresh one when it is nil, so instances started in one process (the test
+	// harness) never share registrations. It is not the shared legacyregistry.
+	Registry metrics.KubeRegistry
+
+	// EventBroadcaster is started by Run, which sends what it records to Client's events API; the
+	// leader-election lock records its events through a recorder built from it.
+	EventBroadcaster record.EventBroadcaster
+
+	// ControllerConfigFile, when non-empty, is the path Run watches to hot start/stop individual
+	// controllers without a process restart. Populated from the --controller-config flag.
+	ControllerConfigFile string
+
+	// ControllerSetManager is non-nil once Run has started watching ControllerConfigFile.
+	ControllerSetManager *controllerset.ControllerSetManager
+}
+
+// CompletedConfig is the completed configuration used to run kube-controller-manager.
+type CompletedConfig struct {
+	*Config
+}
+
+// Complete fills in fields not set that are required to have valid data and can be derived from
+// other fields. It is not (yet) a deep copy.
+func (c *Config) Complete() *CompletedConfig {
+	return &CompletedConfig{c}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2014 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package app implements kube-controller-manager, wiring together the controllers that keep the
+// cluster's actual state converging on its desired state.
+package app
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utiluuid "k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/informers"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/metrics"
+	"k8s.io/controller-manager/pkg/clientbuilder"
+	kubecontrollerconfig "k8s.io/kubernetes/cmd/kube-controller-manager/app/config"
+	"k8s.io/kubernetes/cmd/kube-controller-manager/app/controllerset"
+
+	"k8s.io/klog/v2"
+)
+
+// ControllerContext, InitFunc and ControllerSetManager live in the controllerset package so that
+// both app and app/config can depend on them without an import cycle; they're aliased here because
+// they're conceptually part of app's public surface.
+type (
+	ControllerContext    = controllerset.ControllerContext
+	InitFunc             = controllerset.InitFunc
+	ControllerSetManager = controllerset.ControllerSetManager
+)
+
+// NewControllerSetManager is controllerset.NewControllerSetManager, re-exported for callers that
+// otherwise only depend on app.
+var NewControllerSetManager = controllerset.NewControllerSetManager
+
+// ControllerLoopMode identifies the kind of controller loops kube-controller-manager is running.
+type ControllerLoopMode int
+
+const (
+	// IncludeCloudLoops means the cloud-provider-backed controller loops are included.
+	IncludeCloudLoops ControllerLoopMode = iota
+	// ExternalLoops means cloud-provider-backed controller loops are run by an external
+	// cloud-controller-manager instead.
+	ExternalLoops
+)
+
+// ControllersDisabledByDefault is the set of controllers that are off by default. It is passed to
+// KubeControllerManagerOptions.Flags and Config, which do not consult it.
+var ControllersDisabledByDefault = sets.NewString(
+	"bootstrapsigner",
+	"tokencleaner",
+)
+
+// KnownControllers returns the names of every controller kube-controller-manager knows how to run,
+// sorted.
+func KnownControllers() []string {
+	return sets.StringKeySet(NewControllerInitializers(IncludeCloudLoops)).List()
+}
+
+// NewControllerInitializers returns the known set of controllers, keyed by name, appropriate for
+// loopMode.
+func NewControllerInitializers(loopMode ControllerLoopMode) map[string]InitFunc {
+	controllers := map[string]InitFunc{}
+	controllers["endpoint"] = startEndpointController
+	controllers["node"] = startNodeController
+	if loopMode == IncludeCloudLoops {
+		controllers["cloud-node-lifecycle"] = startCloudNodeLifecycleController
+	}
+	return controllers
+}
+
+// Run runs the KubeControllerManagerOptions. This should never exit, and ctx should be cancelled to
+// initiate a graceful shutdown. If LeaderElection.LeaderElect is set, the controllers are only started
+// once this instance acquires the leader lease, and are stopped if it's lost.
+func Run(ctx context.Context, c *kubecontrollerconfig.CompletedConfig) error {
+	if !c.LeaderElection.LeaderElect {
+		return run(ctx, c)
+	}
+	return runWithLeaderElection(ctx, c)
+}
+
+// runWithLeaderElection blocks acquiring the leader lease described by c.LeaderElection, then calls run
+// for as long as this instance holds it. It returns once ctx is cancelled or the lease is lost.
+func runWithLeaderElection(ctx context.Context, c *kubecontrollerconfig.CompletedConfig) error {
+	id, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	id = id + "_" + string(utiluuid.NewUUID())
+
+	var recorder record.EventRecorder
+	if c.EventBroadcaster != nil {
+		recorder = c.EventBroadcaster.NewRecorder(clientgoscheme.Scheme, corev1.EventSource{Component: c.LeaderElection.ResourceName})
+	}
+
+	rl, err := resourcelock.New(
+		c.LeaderElection.ResourceLock,
+		c.LeaderElection.ResourceNamespace,
+		c.LeaderElection.ResourceName,
+		c.Client.CoreV1(),
+		c.Client.CoordinationV1(),
+		resourcelock.ResourceLockConfig{
+			Identity:      id,
+			EventRecorder: recorder,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	var runErr error
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:          rl,
+		LeaseDuration: c.LeaderElection.LeaseDuration.Duration,
+		RenewDeadline: c.LeaderElection.RenewDeadline.Duration,
+		RetryPeriod:   c.LeaderElection.RetryPeriod.Duration,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				runErr = run(ctx, c)
+			},
+			OnStoppedLeading: func() {
+				klog.Infof("%s: leader election lost", c.LeaderElection.ResourceName)
+			},
+		},
+		Name: c.LeaderElection.ResourceName,
+	})
+	return runErr
+}
+
+// run does the actual work of starting kube-controller-manager's controllers and serving its
+// healthz/debug endpoints; it is what Run calls directly, or only while this instance holds the
+// leader lease.
+func run(ctx context.Context, c *kubecontrollerconfig.CompletedConfig) error {
+	if c.EventBroadcaster != nil && c.Client != nil {
+		c.EventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: c.Client.CoreV1().Events("")})
+		defer c.EventBroadcaster.Shutdown()
+	}
+
+	if c.ControllerConfigFile != "" {
+		registry := c.Registry
+		if registry == nil {
+			registry = metrics.NewKubeRegistry()
+		}
+		informerFactory := sharedInformersOrDefault(c)
+		manager := NewControllerSetManager(ControllerContext{
+			ClientBuilder:   clientbuilder.SimpleControllerClientBuilder{ClientConfig: c.Kubeconfig},
+			InformerFactory: informerFactory,
+			Stop:            ctx.Done(),
+		}, NewControllerInitializers(IncludeCloudLoops), c.ControllerConfigFile, registry)
+		c.ControllerSetManager = manager
+
+		// Starts the informers requested so far. The ControllerSetManager calls Start again after
+		// constructing each controller, so the informers that controller requested begin their
+		// List/Watch too instead of the controller waiting forever on a cache that never syncs.
+		informerFactory.Start(ctx.Done())
+
+		go func() {
+			if err := manager.Run(ctx.Done()); err != nil {
+				klog.Errorf("controller-set-manager exited: %v", err)
+			}
+		}()
+	}
+
+	if c.SecureServing != nil {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		if c.ControllerSetManager != nil {
+			mux.Handle("/debug/controllers", c.ControllerSetManager)
+		}
+		if _, err := c.SecureServing.Serve(mux, 30*time.Second, ctx.Done()); err != nil {
+			return err
+		}
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// RunWithStopChannel is a shim preserving the pre-context.Context Run(stopCh) signature for callers
+// that haven't migrated yet; it blocks until stopCh is closed. New callers should prefer Run, which
+// takes a context.Context and so can also carry deadlines, values, and tracing spans.
+func RunWithStopChannel(stopCh <-chan struct{}, c *kubecontrollerconfig.CompletedConfig) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return Run(ctx, c)
+}
+
+func sharedInformersOrDefault(c *kubecontrollerconfig.CompletedConfig) informers.SharedInformerFactory {
+	if c.SharedInformers != nil {
+		return c.SharedInformers
+	}
+	return informers.NewSharedInformerFactory(c.Client, 0)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+
+	"k8s.io/controller-manager/controller"
+	endpointcontroller "k8s.io/kubernetes/pkg/controller/endpoint"
+	"k8s.io/kubernetes/pkg/controller/nodelifecycle"
+)
+
+func startEndpointController(ctx ControllerContext) (controller.Interface, bool, error) {
+	c := endpointcontroller.NewEndpointController(
+		ctx.InformerFactory.Core().V1().Pods(),
+		ctx.InformerFactory.Core().V1().Services(),
+		ctx.InformerFactory.Core().V1().Endpoints(),
+		ctx.ClientBuilder.ClientOrDie("endpoint-controller"),
+		0,
+	)
+	return runFunc(func(runCtx context.Context) {
+		c.Run(1, runCtx.Done())
+	}), true, nil
+}
+
+func startNodeController(ctx ControllerContext) (controller.Interface, bool, error) {
+	c, err := nodelifecycle.NewNodeLifecycleController(
+		ctx.InformerFactory.Coordination().V1().Leases(),
+		ctx.InformerFactory.Core().V1().Pods(),
+		ctx.InformerFactory.Core().V1().Nodes(),
+		ctx.InformerFactory.Apps().V1().DaemonSets(),
+		ctx.ClientBuilder.ClientOrDie("node-controller"),
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	return runFunc(func(runCtx context.Context) {
+		c.Run(runCtx.Done())
+	}), true, nil
+}
+
+func startCloudNodeLifecycleController(ctx ControllerContext) (controller.Interface, bool, error) {
+	// Registered only when kube-controller-manager owns the cloud loops (ControllerLoopMode ==
+	// IncludeCloudLoops). It constructs no controller and always reports itself as disabled.
+	return nil, false, nil
+}
+
+// runFunc adapts a plain func(context.Context) to controller.Interface, for controllers (like
+// endpointcontroller and nodelifecycle above) whose real Run method doesn't itself take a
+// context.Context. It does no work until its caller invokes Run — construction in the InitFuncs
+// above never starts the controller on its own, so whoever calls Run (ControllerSetManager for a
+// running process, ControllerHandle.Sync in the embedded test harness) has exclusive control over
+// when reconciliation actually begins.
+type runFunc func(ctx context.Context)
+
+func (f runFunc) Run(ctx context.Context) { f(ctx) }
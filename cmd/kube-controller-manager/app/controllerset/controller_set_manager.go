@@ -0,0 +1,251 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controllerset implements a hot-reloadable set of controllers, driven by a config file
+// listing which controllers should be running. It lives in its own package, rather than directly
+// in app, so that both app (which runs it) and app/config (whose Config needs to reference it) can
+// import it without an import cycle.
+package controllerset
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/component-base/metrics"
+	"k8s.io/controller-manager/controller"
+	"k8s.io/controller-manager/pkg/clientbuilder"
+	"k8s.io/klog/v2"
+)
+
+// ControllerContext is what an InitFunc needs to construct and run its controller.
+type ControllerContext struct {
+	// ClientBuilder builds the clients each controller authenticates with.
+	ClientBuilder clientbuilder.ControllerClientBuilder
+	// InformerFactory is shared by every controller started in the same process.
+	InformerFactory informers.SharedInformerFactory
+	// Stop is closed when the controller should shut down.
+	Stop <-chan struct{}
+}
+
+// InitFunc constructs a controller, returning it along with whether it is enabled. Controllers that
+// report themselves as disabled return a nil controller and enabled=false. InitFunc only constructs
+// the controller and must not start it; the caller is responsible for calling Run on the returned
+// controller.Interface once it wants reconciliation to begin.
+type InitFunc func(ctx ControllerContext) (controller.Interface, bool, error)
+
+// ControllerSetManager watches a controller-config file on disk and starts or stops individual
+// controllers in response to changes to it, without requiring the kube-controller-manager process
+// to be restarted. The file lists controller names separated by commas or newlines: "name" enables
+// a controller, "-name" disables it, and "*" (or an empty file) enables every known controller.
+type ControllerSetManager struct {
+	lock sync.Mutex
+
+	ctx       ControllerContext
+	initFuncs map[string]InitFunc
+	allNames  sets.String
+
+	configPath   string
+	pollInterval time.Duration
+
+	running map[string]context.CancelFunc
+
+	startTotal *metrics.CounterVec
+	stopTotal  *metrics.CounterVec
+}
+
+// NewControllerSetManager creates a ControllerSetManager that can start or stop any controller named
+// in initFuncs, using ctx to build each controller before running it. start/stop counts are
+// registered against registry rather than the global legacyregistry, so that several
+// ControllerSetManagers constructed in the same process (e.g. by parallel test servers) don't panic
+// each other with duplicate metric registration.
+func NewControllerSetManager(ctx ControllerContext, initFuncs map[string]InitFunc, configPath string, registry metrics.KubeRegistry) *ControllerSetManager {
+	m := &ControllerSetManager{
+		ctx:          ctx,
+		initFuncs:    initFuncs,
+		allNames:     sets.StringKeySet(initFuncs),
+		configPath:   configPath,
+		pollInterval: 2 * time.Second,
+		running:      map[string]context.CancelFunc{},
+		startTotal: metrics.NewCounterVec(
+			&metrics.CounterOpts{
+				Subsystem:      "controller_set_manager",
+				Name:           "controller_start_total",
+				Help:           "Number of times a controller was started by the ControllerSetManager, by controller name.",
+				StabilityLevel: metrics.ALPHA,
+			},
+			[]string{"controller"},
+		),
+		stopTotal: metrics.NewCounterVec(
+			&metrics.CounterOpts{
+				Subsystem:      "controller_set_manager",
+				Name:           "controller_stop_total",
+				Help:           "Number of times a controller was stopped by the ControllerSetManager, by controller name.",
+				StabilityLevel: metrics.ALPHA,
+			},
+			[]string{"controller"},
+		),
+	}
+	registry.MustRegister(m.startTotal, m.stopTotal)
+	return m
+}
+
+// Run loads the initial controller set from configPath, starts it, and then polls configPath for
+// changes until stopCh is closed, reconciling the running controllers to match each time the file
+// changes. It returns once every started controller has been stopped.
+func (m *ControllerSetManager) Run(stopCh <-chan struct{}) error {
+	enabled, err := m.readEnabled()
+	if err != nil {
+		return err
+	}
+	m.reconcile(enabled)
+
+	wait.Until(func() {
+		enabled, err := m.readEnabled()
+		if err != nil {
+			klog.Errorf("controller-set-manager: failed to read %q: %v", m.configPath, err)
+			return
+		}
+		m.reconcile(enabled)
+	}, m.pollInterval, stopCh)
+
+	<-stopCh
+	m.reconcile(sets.String{})
+	return nil
+}
+
+// LiveControllers returns the names of the controllers currently running, sorted for stable output.
+func (m *ControllerSetManager) LiveControllers() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	names := make([]string, 0, len(m.running))
+	for name := range m.running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServeHTTP implements the /debug/controllers endpoint, listing the currently live controllers as JSON.
+func (m *ControllerSetManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(struct {
+		Controllers []string `json:"controllers"`
+	}{Controllers: m.LiveControllers()}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (m *ControllerSetManager) readEnabled() (sets.String, error) {
+	raw, err := os.ReadFile(m.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := strings.FieldsFunc(string(raw), func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+
+	enabled := sets.String{}
+	if len(tokens) == 0 || sets.NewString(tokens...).Has("*") {
+		enabled = m.allNames.Union(nil)
+	}
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		switch {
+		case token == "" || token == "*":
+			continue
+		case strings.HasPrefix(token, "-"):
+			enabled.Delete(strings.TrimPrefix(token, "-"))
+		default:
+			enabled.Insert(token)
+		}
+	}
+	return enabled.Intersection(m.allNames), nil
+}
+
+func (m *ControllerSetManager) reconcile(enabled sets.String) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for name := range m.running {
+		if enabled.Has(name) {
+			continue
+		}
+		m.stopLocked(name)
+	}
+
+	for _, name := range enabled.List() {
+		if _, ok := m.running[name]; ok {
+			continue
+		}
+		m.startLocked(name)
+	}
+}
+
+func (m *ControllerSetManager) startLocked(name string) {
+	initFn, ok := m.initFuncs[name]
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	go func() {
+		ctrlCtx := m.ctx
+		ctrlCtx.Stop = ctx.Done()
+		c, enabled, err := initFn(ctrlCtx)
+		if err != nil {
+			klog.Errorf("controller-set-manager: failed to start %q: %v", name, err)
+			return
+		}
+		if !enabled || c == nil {
+			klog.Infof("controller-set-manager: %q reported itself as disabled", name)
+			return
+		}
+		// A shared informer factory only starts the informers requested before its Start call, so
+		// start it again now that initFn has requested this controller's informers.
+		if m.ctx.InformerFactory != nil {
+			m.ctx.InformerFactory.Start(m.ctx.Stop)
+		}
+		// initFn only constructs the controller; running it is this goroutine's job, so it
+		// actually reconciles instead of sitting constructed-but-idle until ctx is cancelled.
+		c.Run(ctx)
+	}()
+
+	m.running[name] = cancel
+	m.startTotal.WithLabelValues(name).Inc()
+	klog.Infof("controller-set-manager: started %q", name)
+}
+
+func (m *ControllerSetManager) stopLocked(name string) {
+	cancel, ok := m.running[name]
+	if !ok {
+		return
+	}
+	cancel()
+	delete(m.running, name)
+	m.stopTotal.WithLabelValues(name).Inc()
+	klog.Infof("controller-set-manager: stopped %q", name)
+}
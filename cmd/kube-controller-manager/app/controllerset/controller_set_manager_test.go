@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllerset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/metrics"
+	"k8s.io/controller-manager/controller"
+)
+
+func newTestManager(t *testing.T, configContents string) *ControllerSetManager {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "controller-config")
+	if err := os.WriteFile(path, []byte(configContents), 0644); err != nil {
+		t.Fatalf("failed to write controller config: %v", err)
+	}
+
+	initFuncs := map[string]InitFunc{
+		"endpoint": func(ControllerContext) (controller.Interface, bool, error) { return nil, true, nil },
+		"node":     func(ControllerContext) (controller.Interface, bool, error) { return nil, true, nil },
+	}
+	return NewControllerSetManager(ControllerContext{}, initFuncs, path, metrics.NewKubeRegistry())
+}
+
+func TestReadEnabled(t *testing.T) {
+	cases := []struct {
+		name     string
+		contents string
+		want     sets.String
+	}{
+		{name: "empty file means everything", contents: "", want: sets.NewString("endpoint", "node")},
+		{name: "star means everything", contents: "*", want: sets.NewString("endpoint", "node")},
+		{name: "explicit list", contents: "endpoint\n", want: sets.NewString("endpoint")},
+		{name: "comma separated", contents: "endpoint,node", want: sets.NewString("endpoint", "node")},
+		{name: "star minus name", contents: "*,-node", want: sets.NewString("endpoint")},
+		{name: "unknown names are dropped", contents: "endpoint,bogus", want: sets.NewString("endpoint")},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := newTestManager(t, c.contents)
+			got, err := m.readEnabled()
+			if err != nil {
+				t.Fatalf("readEnabled: %v", err)
+			}
+			if !got.Equal(c.want) {
+				t.Errorf("readEnabled() = %v, want %v", got.List(), c.want.List())
+			}
+		})
+	}
+}
+
+func TestReconcileStartsAndStopsControllers(t *testing.T) {
+	m := newTestManager(t, "endpoint")
+
+	m.reconcile(sets.NewString("endpoint"))
+	if got := m.LiveControllers(); !sets.NewString(got...).Equal(sets.NewString("endpoint")) {
+		t.Fatalf("LiveControllers() after starting endpoint = %v, want [endpoint]", got)
+	}
+
+	m.reconcile(sets.NewString("node"))
+	if got := m.LiveControllers(); !sets.NewString(got...).Equal(sets.NewString("node")) {
+		t.Fatalf("LiveControllers() after reconciling to node = %v, want [node]", got)
+	}
+
+	m.reconcile(sets.String{})
+	if got := m.LiveControllers(); len(got) != 0 {
+		t.Fatalf("LiveControllers() after reconciling to nothing = %v, want none", got)
+	}
+}
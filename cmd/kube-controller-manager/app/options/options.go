@@ -0,0 +1,161 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package options contains the flags and options used when launching kube-controller-manager.
+package options
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiserveroptions "k8s.io/apiserver/pkg/server/options"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	cliflag "k8s.io/component-base/cli/flag"
+	componentbaseconfig "k8s.io/component-base/config"
+	"k8s.io/component-base/metrics"
+	kubecontrollerconfig "k8s.io/kubernetes/cmd/kube-controller-manager/app/config"
+)
+
+// GenericControllerManagerConfiguration holds configuration shared by every controller.
+type GenericControllerManagerConfiguration struct {
+	LeaderElection componentbaseconfig.LeaderElectionConfiguration
+}
+
+// KubeControllerManagerConfiguration is the in-memory, typed configuration for
+// kube-controller-manager.
+type KubeControllerManagerConfiguration struct {
+	Generic GenericControllerManagerConfiguration
+}
+
+// GenericControllerManagerConfigurationOptions wraps KubeControllerManagerConfiguration so it can
+// be exposed on the command line and defaulted independently of the rest of the options.
+type GenericControllerManagerConfigurationOptions struct {
+	ComponentConfig KubeControllerManagerConfiguration
+}
+
+// AddFlags adds the generic kube-controller-manager flags to fs.
+func (o *GenericControllerManagerConfigurationOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.ComponentConfig.Generic.LeaderElection.LeaderElect, "leader-elect", o.ComponentConfig.Generic.LeaderElection.LeaderElect,
+		"Start a leader election client and gain leadership before executing the main loop.")
+	fs.StringVar(&o.ComponentConfig.Generic.LeaderElection.ResourceName, "leader-elect-resource-name", o.ComponentConfig.Generic.LeaderElection.ResourceName,
+		"The name of resource object that is used for locking during leader election.")
+	fs.StringVar(&o.ComponentConfig.Generic.LeaderElection.ResourceNamespace, "leader-elect-resource-namespace", o.ComponentConfig.Generic.LeaderElection.ResourceNamespace,
+		"The namespace of resource object that is used for locking during leader election.")
+}
+
+// KubeControllerManagerOptions is all the parameters needed to start kube-controller-manager.
+type KubeControllerManagerOptions struct {
+	Generic       *GenericControllerManagerConfigurationOptions
+	SecureServing *apiserveroptions.SecureServingOptionsWithLoopback
+
+	Master     string
+	Kubeconfig string
+
+	// KubeconfigOverride, when set, is used as the rest config every controller client is built
+	// from instead of the one derived from Master/Kubeconfig. It has no corresponding flag; it
+	// exists for callers constructing KubeControllerManagerOptions programmatically (e.g. the test
+	// server) that already have a rest.Config for the apiserver they want controllers to talk to.
+	KubeconfigOverride *restclient.Config
+
+	// ControllerConfigFile is the path the ControllerSetManager watches to hot start/stop
+	// individual controllers. Set via --controller-config.
+	ControllerConfigFile string
+}
+
+// NewKubeControllerManagerOptions creates a new KubeControllerManagerOptions with default values.
+func NewKubeControllerManagerOptions() (*KubeControllerManagerOptions, error) {
+	s := &KubeControllerManagerOptions{
+		Generic: &GenericControllerManagerConfigurationOptions{
+			ComponentConfig: KubeControllerManagerConfiguration{
+				Generic: GenericControllerManagerConfiguration{
+					LeaderElection: componentbaseconfig.LeaderElectionConfiguration{
+						LeaderElect:       true,
+						LeaseDuration:     metav1.Duration{Duration: 15 * time.Second},
+						RenewDeadline:     metav1.Duration{Duration: 10 * time.Second},
+						RetryPeriod:       metav1.Duration{Duration: 2 * time.Second},
+						ResourceLock:      "leases",
+						ResourceName:      "kube-controller-manager",
+						ResourceNamespace: "kube-system",
+					},
+				},
+			},
+		},
+		SecureServing: apiserveroptions.NewSecureServingOptions().WithLoopback(),
+	}
+	return s, nil
+}
+
+// Flags returns the complete set of flags for kube-controller-manager, grouped by FlagSet name.
+func (s *KubeControllerManagerOptions) Flags(allControllers, disabledByDefaultControllers []string) cliflag.NamedFlagSets {
+	fss := cliflag.NamedFlagSets{}
+
+	genericFS := fss.FlagSet("generic")
+	genericFS.StringVar(&s.Master, "master", s.Master, "The address of the Kubernetes API server (overrides any value in kubeconfig).")
+	genericFS.StringVar(&s.Kubeconfig, "kubeconfig", s.Kubeconfig, "Path to kubeconfig file with authorization and master location information.")
+	genericFS.StringVar(&s.ControllerConfigFile, "controller-config", s.ControllerConfigFile,
+		"Path to a file listing enabled controllers, separated by commas or newlines ('*' for all, "+
+			"'-name' to disable one); when set, the "+
+			"ControllerSetManager watches it and starts/stops controllers as it changes, without "+
+			"requiring a restart.")
+	s.Generic.AddFlags(genericFS)
+
+	s.SecureServing.AddFlags(fss.FlagSet("secure serving"))
+
+	return fss
+}
+
+// Config returns a kube-controller-manager Config given the set of controllers and flags provided.
+func (s *KubeControllerManagerOptions) Config(allControllers, disabledByDefaultControllers []string) (*kubecontrollerconfig.Config, error) {
+	kubeconfig := s.KubeconfigOverride
+	if kubeconfig == nil {
+		var err error
+		kubeconfig, err = clientcmd.BuildConfigFromFlags(s.Master, s.Kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := kubernetes.NewForConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &kubecontrollerconfig.Config{
+		Kubeconfig:           kubeconfig,
+		Client:               client,
+		SharedInformers:      informers.NewSharedInformerFactory(client, 0),
+		LeaderElection:       s.Generic.ComponentConfig.Generic.LeaderElection,
+		Registry:             metrics.NewKubeRegistry(),
+		EventBroadcaster:     record.NewBroadcaster(),
+		ControllerConfigFile: s.ControllerConfigFile,
+	}
+
+	if s.SecureServing.BindPort != 0 {
+		if err := s.SecureServing.ApplyTo(&c.SecureServing, &c.LoopbackClientConfig); err != nil {
+			return nil, err
+		}
+	} else {
+		c.LoopbackClientConfig = restclient.CopyConfig(kubeconfig)
+	}
+
+	return c, nil
+}
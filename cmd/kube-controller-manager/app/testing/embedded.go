@@ -0,0 +1,171 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/client-go/informers"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/controller-manager/controller"
+	"k8s.io/controller-manager/pkg/clientbuilder"
+	"k8s.io/kubernetes/cmd/kube-controller-manager/app"
+)
+
+// EmbeddedOptions configures StartEmbedded. Unlike StartTestServer, no HTTPS serving stack is
+// started: controllers are constructed and run in-process against the caller-supplied apiserver
+// and informer factory.
+type EmbeddedOptions struct {
+	// Apiserver is the rest client config used to build every controller's clients.
+	Apiserver *restclient.Config
+	// Controllers lists the names of the controllers to construct, as returned by
+	// app.KnownControllers.
+	Controllers []string
+	// SharedInformerFactory is reused by every controller instead of each one creating its own,
+	// so tests control exactly when informers start and resync.
+	SharedInformerFactory informers.SharedInformerFactory
+}
+
+// ControllerHandle is returned for each controller started by StartEmbedded. It exposes the
+// underlying controller.Interface plus a Sync helper that starts the controller and waits for the
+// shared informers' initial sync, so tests need not poll for eventual consistency.
+type ControllerHandle struct {
+	Name       string
+	Controller controller.Interface
+
+	informerFactory informers.SharedInformerFactory
+	runCtx          context.Context
+	startOnce       sync.Once
+	cancel          context.CancelFunc
+}
+
+// Sync starts the controller's Run loop the first time it's called, then blocks until every
+// informer it depends on has completed its initial list, or ctx is cancelled, whichever comes
+// first. It is meant to let tests advance a controller past its initial sync deterministically,
+// instead of polling for eventual consistency or blocking forever on Run itself.
+func (h *ControllerHandle) Sync(ctx context.Context) error {
+	h.startOnce.Do(func() {
+		go h.Controller.Run(h.runCtx)
+	})
+
+	synced := h.informerFactory.WaitForCacheSync(ctx.Done())
+	for informerType, ok := range synced {
+		if !ok {
+			return fmt.Errorf("cache did not sync for %v before %q's context was done", informerType, h.Name)
+		}
+	}
+	return nil
+}
+
+// Stop cancels this controller's run loop.
+func (h *ControllerHandle) Stop() {
+	if h.cancel != nil {
+		h.cancel()
+	}
+}
+
+// NodeControllerHandle wraps the node lifecycle controller's handle.
+type NodeControllerHandle struct{ *ControllerHandle }
+
+// EndpointsControllerHandle wraps the endpoints controller's handle.
+type EndpointsControllerHandle struct{ *ControllerHandle }
+
+// EmbeddedServer is the result of StartEmbedded: typed handles for every controller that was
+// requested, plus a TearDownFn that stops them all.
+type EmbeddedServer struct {
+	Node       *NodeControllerHandle
+	Endpoints  *EndpointsControllerHandle
+	Handles    map[string]*ControllerHandle
+	TearDownFn TearDownFunc
+}
+
+// StartEmbedded constructs the requested controllers in-process, against opts.Apiserver and
+// opts.SharedInformerFactory, and returns handles for driving them directly. It never binds a
+// port or serves HTTPS, and it never waits on the 30s /healthz poll that StartTestServer does,
+// making it suitable for fast, deterministic unit-style tests of controller interactions.
+func StartEmbedded(t Logger, opts EmbeddedOptions) (result EmbeddedServer, err error) {
+	result.Handles = map[string]*ControllerHandle{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tearDown := func() {
+		for _, h := range result.Handles {
+			h.Stop()
+		}
+		cancel()
+	}
+	defer func() {
+		if err != nil {
+			tearDown()
+		}
+	}()
+
+	all := app.NewControllerInitializers(app.IncludeCloudLoops)
+	initFuncs := make(map[string]app.InitFunc, len(opts.Controllers))
+	for _, name := range opts.Controllers {
+		initFn, ok := all[name]
+		if !ok {
+			return result, fmt.Errorf("unknown controller %q", name)
+		}
+		initFuncs[name] = initFn
+	}
+
+	clientBuilder := clientbuilder.SimpleControllerClientBuilder{ClientConfig: opts.Apiserver}
+
+	for name, initFn := range initFuncs {
+		ctrlCtx, ctrlCancel := context.WithCancel(ctx)
+		ctrlCtx2 := app.ControllerContext{
+			ClientBuilder:   clientBuilder,
+			InformerFactory: opts.SharedInformerFactory,
+			Stop:            ctrlCtx.Done(),
+		}
+
+		c, enabled, err := initFn(ctrlCtx2)
+		if err != nil {
+			ctrlCancel()
+			return result, fmt.Errorf("failed to construct %q: %v", name, err)
+		}
+		if !enabled || c == nil {
+			ctrlCancel()
+			t.Logf("%q reported itself as disabled, skipping", name)
+			continue
+		}
+
+		handle := &ControllerHandle{
+			Name:            name,
+			Controller:      c,
+			informerFactory: opts.SharedInformerFactory,
+			runCtx:          ctrlCtx,
+			cancel:          ctrlCancel,
+		}
+		result.Handles[name] = handle
+
+		switch name {
+		case "node", "nodelifecycle":
+			result.Node = &NodeControllerHandle{handle}
+		case "endpoint":
+			result.Endpoints = &EndpointsControllerHandle{handle}
+		}
+	}
+
+	opts.SharedInformerFactory.Start(ctx.Done())
+
+	result.TearDownFn = tearDown
+	return result, nil
+}
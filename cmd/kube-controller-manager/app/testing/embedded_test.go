@@ -0,0 +1,97 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/informers"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/controller-manager/controller"
+)
+
+// fakeController counts how many times Run is called and blocks until ctx is cancelled, so tests can
+// tell whether ControllerHandle.Sync started it, and that it only ever does so once. started fires
+// once per Run call so tests can wait for the first start instead of racing on the counter.
+type fakeController struct {
+	mu      sync.Mutex
+	starts  int
+	started chan struct{}
+}
+
+func newFakeController() *fakeController {
+	return &fakeController{started: make(chan struct{}, 1)}
+}
+
+func (f *fakeController) Run(ctx context.Context) {
+	f.mu.Lock()
+	f.starts++
+	f.mu.Unlock()
+	f.started <- struct{}{}
+	<-ctx.Done()
+}
+
+func (f *fakeController) Starts() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.starts
+}
+
+func newTestHandle(c controller.Interface) (*ControllerHandle, context.CancelFunc) {
+	runCtx, cancel := context.WithCancel(context.Background())
+	factory := informers.NewSharedInformerFactory(fakeclientset.NewSimpleClientset(), 0)
+	return &ControllerHandle{
+		Name:            "fake",
+		Controller:      c,
+		informerFactory: factory,
+		runCtx:          runCtx,
+		cancel:          cancel,
+	}, cancel
+}
+
+func TestControllerHandleSyncStartsControllerOnce(t *testing.T) {
+	c := newFakeController()
+	h, cancel := newTestHandle(c)
+	defer cancel()
+
+	ctx, syncCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer syncCancel()
+
+	if err := h.Sync(ctx); err != nil {
+		t.Fatalf("first Sync: %v", err)
+	}
+
+	select {
+	case <-c.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Sync did not start the controller")
+	}
+
+	// sync.Once means this cannot spawn a second Run, no matter how long we wait for one.
+	if err := h.Sync(ctx); err != nil {
+		t.Fatalf("second Sync: %v", err)
+	}
+
+	h.Stop()
+
+	if got := c.Starts(); got != 1 {
+		t.Errorf("controller started %d times across two Sync calls, want 1", got)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Event is a single controller event or structured log entry captured by an EventSink.
+type Event struct {
+	// CorrelationID ties together events emitted while handling the same piece of work across
+	// controllers, e.g. a replicaset's UID propagated to the pods it creates and on to the nodes
+	// those pods land on, so cross-controller causality can be reconstructed.
+	CorrelationID string
+	Controller    string
+	Reason        string
+	Message       string
+	InvolvedUID   types.UID
+	Timestamp     time.Time
+}
+
+// EventSink receives events emitted by controllers started via StartTestServer or StartEmbedded.
+type EventSink interface {
+	Record(event Event)
+}
+
+// MemoryEventSink is an EventSink that keeps every event in memory for later assertions. It is the
+// default sink used by StartTestServer when --events-sink is not set.
+type MemoryEventSink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// NewMemoryEventSink returns an empty MemoryEventSink.
+func NewMemoryEventSink() *MemoryEventSink {
+	return &MemoryEventSink{}
+}
+
+// Record appends event to the in-memory log.
+func (s *MemoryEventSink) Record(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+// Events returns a copy of every event recorded so far, in the order they were received.
+func (s *MemoryEventSink) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Event, len(s.events))
+	copy(out, s.events)
+	return out
+}
+
+// fileEventSink appends each event as a JSON object to a file, one per line.
+type fileEventSink struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func newFileEventSink(path string) (*fileEventSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open events sink file %q: %v", path, err)
+	}
+	return &fileEventSink{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (s *fileEventSink) Record(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.enc.Encode(event)
+}
+
+func (s *fileEventSink) Close() error {
+	return s.file.Close()
+}
+
+// ParseEventsSinkFlag builds the EventSink named by the --events-sink flag value, which takes the
+// form "file:///path/to/events.json" or "otlp://host:port". Tests that don't pass --events-sink get
+// a MemoryEventSink instead; see StartTestServer.
+func ParseEventsSinkFlag(value string) (EventSink, error) {
+	switch {
+	case strings.HasPrefix(value, "file://"):
+		return newFileEventSink(strings.TrimPrefix(value, "file://"))
+	case strings.HasPrefix(value, "otlp://"):
+		return newOTLPEventSink(strings.TrimPrefix(value, "otlp://"))
+	default:
+		return nil, fmt.Errorf("unsupported --events-sink scheme in %q, want file:// or otlp://", value)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEventsSinkFlagUnsupportedScheme(t *testing.T) {
+	if _, err := ParseEventsSinkFlag("memory://whatever"); err == nil {
+		t.Fatal("expected an error for an unsupported --events-sink scheme, got nil")
+	}
+}
+
+func TestParseEventsSinkFlagFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+
+	sink, err := ParseEventsSinkFlag("file://" + path)
+	if err != nil {
+		t.Fatalf("ParseEventsSinkFlag: %v", err)
+	}
+
+	sink.Record(Event{Controller: "node", Reason: "Created"})
+	closeEventSink(sink)
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open events sink file: %v", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		t.Fatal("expected one line written to the events sink file, got none")
+	}
+	var got Event
+	if err := json.Unmarshal(scanner.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal recorded event: %v", err)
+	}
+	if got.Controller != "node" || got.Reason != "Created" {
+		t.Errorf("recorded event = %+v, want Controller=node Reason=Created", got)
+	}
+}
+
+func TestMemoryEventSink(t *testing.T) {
+	sink := NewMemoryEventSink()
+	sink.Record(Event{Controller: "endpoint", Reason: "Synced"})
+	sink.Record(Event{Controller: "node", Reason: "Tainted"})
+
+	events := sink.Events()
+	if len(events) != 2 {
+		t.Fatalf("len(Events()) = %d, want 2", len(events))
+	}
+	if events[0].Controller != "endpoint" || events[1].Controller != "node" {
+		t.Errorf("Events() = %+v, want endpoint then node in insertion order", events)
+	}
+}
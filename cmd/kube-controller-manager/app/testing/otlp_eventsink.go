@@ -0,0 +1,91 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// otlpEventSink forwards each recorded event to an OpenTelemetry collector as a single-span trace,
+// using CorrelationID as the trace ID's seed so spans for the same piece of work land in the same
+// trace even across controllers.
+type otlpEventSink struct {
+	provider *sdktrace.TracerProvider
+}
+
+func newOTLPEventSink(endpoint string) (*otlpEventSink, error) {
+	exporter, err := otlptracegrpc.New(context.Background(), otlptracegrpc.WithEndpoint(endpoint), otlptracegrpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial OTLP collector at %q: %v", endpoint, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceNameKey.String("kube-controller-manager"))),
+	)
+	return &otlpEventSink{provider: provider}, nil
+}
+
+func (s *otlpEventSink) Record(event Event) {
+	ctx := context.Background()
+	if event.CorrelationID != "" {
+		ctx = oteltrace.ContextWithRemoteSpanContext(ctx, correlationSpanContext(event.CorrelationID))
+	}
+
+	tracer := s.provider.Tracer("kube-controller-manager/testing")
+	_, span := tracer.Start(ctx, event.Reason,
+		oteltrace.WithAttributes(
+			attribute.String("controller", event.Controller),
+			attribute.String("correlation_id", event.CorrelationID),
+			attribute.String("message", event.Message),
+			attribute.String("involved_uid", string(event.InvolvedUID)),
+		),
+	)
+	span.End(oteltrace.WithTimestamp(event.Timestamp))
+}
+
+// correlationSpanContext deterministically derives a trace and span ID from correlationID by
+// hashing it, so every event sharing a CorrelationID starts its span as part of the same trace
+// (as a child of this synthetic remote parent) instead of each becoming its own root trace.
+func correlationSpanContext(correlationID string) oteltrace.SpanContext {
+	sum := sha256.Sum256([]byte(correlationID))
+
+	var traceID oteltrace.TraceID
+	copy(traceID[:], sum[:16])
+	var spanID oteltrace.SpanID
+	copy(spanID[:], sum[16:24])
+
+	return oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: oteltrace.FlagsSampled,
+		Remote:     true,
+	})
+}
+
+func (s *otlpEventSink) Shutdown(ctx context.Context) error {
+	return s.provider.Shutdown(ctx)
+}
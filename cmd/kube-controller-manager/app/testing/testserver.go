@@ -21,13 +21,18 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
+	"k8s.io/component-base/metrics"
 	"k8s.io/kubernetes/cmd/kube-controller-manager/app"
 	kubecontrollerconfig "k8s.io/kubernetes/cmd/kube-controller-manager/app/config"
 	"k8s.io/kubernetes/cmd/kube-controller-manager/app/options"
@@ -43,8 +48,45 @@ type TestServer struct {
 	LoopbackClientConfig *restclient.Config // Rest client config using the magic token
 	Options              *options.KubeControllerManagerOptions
 	Config               *kubecontrollerconfig.Config
-	TearDownFn           TearDownFunc // TearDown function
-	TmpDir               string       // Temp Dir used, by the apiserver
+	Registry             metrics.KubeRegistry // Registry this instance's controllers registered their metrics against
+
+	// ControllerSetManager is set when --controller-config is passed, letting tests start and stop
+	// individual controllers at runtime instead of only at process start.
+	ControllerSetManager *app.ControllerSetManager
+
+	// Ctx is the context passed to app.Run. TearDownFn cancels it to shut the instance down; no
+	// separate cancel func is exposed.
+	Ctx context.Context
+
+	// EventRecorder receives every Event object seen through the events API while this instance
+	// runs. It is a *MemoryEventSink unless --events-sink was passed via customFlags. klog log
+	// entries are not recorded to it.
+	EventRecorder EventSink
+
+	TearDownFn TearDownFunc // TearDown function
+	TmpDir     string       // Temp Dir used, by the apiserver
+}
+
+// TestServerInstanceOptions are the instance options that can be used to tailor a test server to a
+// particular caller, mirroring the instance options accepted by the kube-apiserver test server. They
+// exist so several kube-controller-manager instances can be started in the same test binary without
+// fighting over leader election or metrics registration.
+type TestServerInstanceOptions struct {
+	// Kubeconfig, when set, is used as this instance's rest client config instead of the one derived
+	// from --kubeconfig/--master, letting a test point a kube-controller-manager directly at an
+	// already-running apiserver test instance.
+	Kubeconfig *restclient.Config
+	// LeaderElect enables leader election for this instance. It is combined with ComponentName so that
+	// several instances started in one process acquire distinct locks instead of colliding.
+	LeaderElect bool
+	// ComponentName distinguishes this instance's leader-election lock from any other
+	// kube-controller-manager instance running in the same test process. Defaults to
+	// "kube-controller-manager" when empty. Every instance gets its own metrics registry whatever
+	// its ComponentName.
+	ComponentName string
+	// ExtraEnv lists additional "KEY=VALUE" environment variables that are set for the duration of this
+	// instance's Run call and restored once it tears down.
+	ExtraEnv []string
 }
 
 // Logger allows t.Testing and b.Testing to be passed to StartTestServer and StartTestServerOrDie
@@ -57,14 +99,22 @@ type Logger interface {
 // StartTestServer starts a kube-controller-manager. A rest client config and a tear-down func,
 // and location of the tmpdir are returned.
 //
-// Note: we return a tear-down func instead of a stop channel because the later will leak temporary
-// 		 files that because Golang testing's call to os.Exit will not give a stop channel go routine
-// 		 enough time to remove temporary files.
+// Note: we return a tear-down func instead of relying solely on context cancellation because the
+// 		 later will leak temporary files, as Golang testing's call to os.Exit will not give the
+// 		 cancelled goroutine enough time to remove them.
 func StartTestServer(t Logger, customFlags []string) (result TestServer, err error) {
-	stopCh := make(chan struct{})
+	return StartTestServerWithOptions(t, TestServerInstanceOptions{}, customFlags)
+}
+
+// StartTestServerWithOptions starts a kube-controller-manager as StartTestServer does, but additionally
+// accepts TestServerInstanceOptions so that several instances can be started side-by-side in the same
+// process, each against its own (or a shared, caller-supplied) apiserver.
+func StartTestServerWithOptions(t Logger, instanceOptions TestServerInstanceOptions, customFlags []string) (result TestServer, err error) {
+	ctx, cancel := context.WithCancel(context.Background())
 	var errCh chan error
+	var restoreEnv func()
 	tearDown := func() {
-		close(stopCh)
+		cancel()
 
 		// If the kube-controller-manager was started, let's wait for
 		// it to shutdown clearly.
@@ -74,6 +124,12 @@ func StartTestServer(t Logger, customFlags []string) (result TestServer, err err
 				klog.Errorf("Failed to shutdown test server clearly: %v", err)
 			}
 		}
+		if restoreEnv != nil {
+			restoreEnv()
+		}
+		if s := result.EventRecorder; s != nil {
+			closeEventSink(s)
+		}
 		if len(result.TmpDir) != 0 {
 			os.RemoveAll(result.TmpDir)
 		}
@@ -84,11 +140,29 @@ func StartTestServer(t Logger, customFlags []string) (result TestServer, err err
 		}
 	}()
 
+	restoreEnv, err = setExtraEnv(instanceOptions.ExtraEnv)
+	if err != nil {
+		return result, err
+	}
+
 	result.TmpDir, err = os.MkdirTemp("", "kube-controller-manager")
 	if err != nil {
 		return result, fmt.Errorf("failed to create temp dir: %v", err)
 	}
 
+	// --events-sink isn't one of kube-controller-manager's own flags, so pull it out of customFlags
+	// before the rest are parsed; it streams every Event object seen through the events API to a
+	// file:// or otlp:// destination (klog entries are not streamed). Default to an in-memory sink
+	// so callers always have something to assert against.
+	var eventsSink EventSink = NewMemoryEventSink()
+	customFlags, eventsSinkFlag := extractFlag(customFlags, "--events-sink")
+	if eventsSinkFlag != "" {
+		eventsSink, err = ParseEventsSinkFlag(eventsSinkFlag)
+		if err != nil {
+			return result, fmt.Errorf("failed to parse --events-sink: %v", err)
+		}
+	}
+
 	fs := pflag.NewFlagSet("test", pflag.PanicOnError)
 
 	s, err := options.NewKubeControllerManagerOptions()
@@ -112,25 +186,58 @@ func StartTestServer(t Logger, customFlags []string) (result TestServer, err err
 		t.Logf("kube-controller-manager will listen securely on port %d...", s.SecureServing.BindPort)
 	}
 
+	componentName := instanceOptions.ComponentName
+	if componentName == "" {
+		componentName = "kube-controller-manager"
+	}
+	if instanceOptions.LeaderElect {
+		s.Generic.ComponentConfig.Generic.LeaderElection.LeaderElect = true
+		s.Generic.ComponentConfig.Generic.LeaderElection.ResourceName = componentName
+		s.Generic.ComponentConfig.Generic.LeaderElection.ResourceNamespace = "kube-system"
+	} else {
+		// app.Run now actually gates on LeaderElection.LeaderElect, so instances that didn't ask for
+		// leader election must have it off; otherwise every instance started in this process would
+		// default to on and fight over the same lock, and only one would ever run its controllers.
+		s.Generic.ComponentConfig.Generic.LeaderElection.LeaderElect = false
+	}
+
+	// Give this instance its own metrics registry so several instances started in the same process
+	// don't panic the shared legacyregistry.DefaultGatherer on duplicate controller metric registration.
+	result.Registry = metrics.NewKubeRegistry()
+
+	// Point every controller client this instance builds at the caller-supplied apiserver, not just
+	// the test's own healthz-polling client; setting it before s.Config runs is what makes it apply
+	// to the controllers app.Run actually starts.
+	s.KubeconfigOverride = instanceOptions.Kubeconfig
+
 	config, err := s.Config(all, disabled)
 	if err != nil {
 		return result, fmt.Errorf("failed to create config from options: %v", err)
 	}
+	config.Registry = result.Registry
+
+	result.EventRecorder = eventsSink
 
 	errCh = make(chan error)
-	go func(stopCh <-chan struct{}) {
+	go func(ctx context.Context) {
 		defer close(errCh)
 
-		if err := app.Run(config.Complete(), stopCh); err != nil {
+		if err := app.Run(ctx, config.Complete()); err != nil {
 			errCh <- err
 		}
-	}(stopCh)
+	}(ctx)
 
 	t.Logf("Waiting for /healthz to be ok...")
 	client, err := kubernetes.NewForConfig(config.LoopbackClientConfig)
 	if err != nil {
 		return result, fmt.Errorf("failed to create a client: %v", err)
 	}
+
+	// Controllers report progress and failures as Event objects through the Kubernetes events API,
+	// so watching them there is the most direct way to feed this instance's EventSink regardless of
+	// which controller emitted them.
+	go watchRealEvents(ctx, client, eventsSink)
+
 	err = wait.Poll(100*time.Millisecond, 30*time.Second, func() (bool, error) {
 		select {
 		case err := <-errCh:
@@ -154,6 +261,8 @@ func StartTestServer(t Logger, customFlags []string) (result TestServer, err err
 	result.LoopbackClientConfig = config.LoopbackClientConfig
 	result.Options = s
 	result.Config = config
+	result.ControllerSetManager = config.ControllerSetManager
+	result.Ctx = ctx
 	result.TearDownFn = tearDown
 
 	return result, nil
@@ -170,6 +279,169 @@ func StartTestServerOrDie(t Logger, flags []string) *TestServer {
 	return nil
 }
 
+// setExtraEnv sets the "KEY=VALUE" entries in env, returning a func that restores every key it
+// touched to its prior value (or unsets it, if it wasn't set before) once the caller is done.
+func setExtraEnv(env []string) (restore func(), err error) {
+	type saved struct {
+		key      string
+		value    string
+		wasUnset bool
+	}
+	var prior []saved
+	restore = func() {
+		// Newest first, so a key listed more than once ends at the value it had before the first.
+		for i := len(prior) - 1; i >= 0; i-- {
+			s := prior[i]
+			if s.wasUnset {
+				os.Unsetenv(s.key)
+			} else {
+				os.Setenv(s.key, s.value)
+			}
+		}
+	}
+
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		prevValue, wasSet := os.LookupEnv(key)
+		if err := os.Setenv(key, value); err != nil {
+			restore()
+			return nil, fmt.Errorf("failed to set env %q: %v", kv, err)
+		}
+		prior = append(prior, saved{key: key, value: prevValue, wasUnset: !wasSet})
+	}
+	return restore, nil
+}
+
+// watchRealEvents forwards every corev1.Event added or modified in the cluster to sink, until ctx
+// is cancelled or the watch otherwise ends. Controllers report progress and failures as Event
+// objects through the events API, so this is the one producer that sees events regardless of which
+// controller (or internal event broadcaster) emitted them.
+func watchRealEvents(ctx context.Context, client kubernetes.Interface, sink EventSink) {
+	w, err := client.CoreV1().Events(metav1.NamespaceAll).Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("events sink: failed to watch events: %v", err)
+		return
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case watchEvent, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			event, ok := watchEvent.Object.(*corev1.Event)
+			if !ok {
+				continue
+			}
+			sink.Record(Event{
+				CorrelationID: string(rootOwnerUID(ctx, client, event.InvolvedObject)),
+				Controller:    event.Source.Component,
+				Reason:        event.Reason,
+				Message:       event.Message,
+				InvolvedUID:   event.InvolvedObject.UID,
+				Timestamp:     event.LastTimestamp.Time,
+			})
+		}
+	}
+}
+
+// maxOwnerChainDepth bounds rootOwnerUID's walk up an object's owner references, so a (theoretically
+// impossible, but not worth trusting) cycle can't hang the events watch loop.
+const maxOwnerChainDepth = 10
+
+// rootOwnerUID walks obj's chain of controller owner references (e.g. a Pod owned by a ReplicaSet
+// owned by a Deployment) up to the object with no controller owner, and returns that root's UID.
+// Events whose InvolvedObject traces back to the same root then share a CorrelationID, so causally
+// related events from different controllers (e.g. a ReplicaSet's pod-created event and that same
+// pod's later node-scheduled event) can be grouped even though their InvolvedObject UIDs differ.
+// Falls back to obj's own UID when no owner can be resolved: unknown kind, lookup failure, or no
+// controller owner reference.
+func rootOwnerUID(ctx context.Context, client kubernetes.Interface, obj corev1.ObjectReference) types.UID {
+	ref := obj
+	for i := 0; i < maxOwnerChainDepth; i++ {
+		owner, ok := controllerOwnerOf(ctx, client, ref)
+		if !ok {
+			return ref.UID
+		}
+		ref = owner
+	}
+	return ref.UID
+}
+
+// controllerOwnerOf looks up ref's controller owner reference, if any. Only Pod and ReplicaSet are
+// resolved, since those are the kinds this package's own controllers (endpoint, node) causally chain
+// pods through; any other kind ends the walk in rootOwnerUID.
+func controllerOwnerOf(ctx context.Context, client kubernetes.Interface, ref corev1.ObjectReference) (corev1.ObjectReference, bool) {
+	var owners []metav1.OwnerReference
+	switch ref.Kind {
+	case "Pod":
+		pod, err := client.CoreV1().Pods(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return corev1.ObjectReference{}, false
+		}
+		owners = pod.OwnerReferences
+	case "ReplicaSet":
+		rs, err := client.AppsV1().ReplicaSets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return corev1.ObjectReference{}, false
+		}
+		owners = rs.OwnerReferences
+	default:
+		return corev1.ObjectReference{}, false
+	}
+
+	for _, o := range owners {
+		if o.Controller != nil && *o.Controller {
+			return corev1.ObjectReference{Kind: o.Kind, Namespace: ref.Namespace, Name: o.Name, UID: o.UID}, true
+		}
+	}
+	return corev1.ObjectReference{}, false
+}
+
+// closeEventSink releases any resources (open files, network connections) held by sink, for sink
+// implementations that need it. MemoryEventSink needs no cleanup and isn't one of them.
+func closeEventSink(sink EventSink) {
+	type closer interface {
+		Close() error
+	}
+	type shutdowner interface {
+		Shutdown(ctx context.Context) error
+	}
+
+	switch s := sink.(type) {
+	case closer:
+		if err := s.Close(); err != nil {
+			klog.Errorf("Failed to close events sink: %v", err)
+		}
+	case shutdowner:
+		if err := s.Shutdown(context.Background()); err != nil {
+			klog.Errorf("Failed to shut down events sink: %v", err)
+		}
+	}
+}
+
+// extractFlag pulls every occurrence of "--name=value" or "--name value" out of flags, returning
+// the remaining flags and the value of the last one (or "" if name wasn't present).
+func extractFlag(flags []string, name string) ([]string, string) {
+	remaining := make([]string, 0, len(flags))
+	value := ""
+	for i := 0; i < len(flags); i++ {
+		switch {
+		case strings.HasPrefix(flags[i], name+"="):
+			value = strings.TrimPrefix(flags[i], name+"=")
+		case flags[i] == name && i+1 < len(flags):
+			value = flags[i+1]
+			i++
+		default:
+			remaining = append(remaining, flags[i])
+		}
+	}
+	return remaining, value
+}
+
 func createListenerOnFreePort() (net.Listener, int, error) {
 	ln, err := net.Listen("tcp", ":0")
 	if err != nil {
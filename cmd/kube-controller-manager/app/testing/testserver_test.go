@@ -0,0 +1,101 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestExtractFlag(t *testing.T) {
+	cases := []struct {
+		name      string
+		flags     []string
+		flagName  string
+		wantRest  []string
+		wantValue string
+	}{
+		{
+			name:      "absent",
+			flags:     []string{"--master=foo", "--kubeconfig=bar"},
+			flagName:  "--events-sink",
+			wantRest:  []string{"--master=foo", "--kubeconfig=bar"},
+			wantValue: "",
+		},
+		{
+			name:      "equals form",
+			flags:     []string{"--master=foo", "--events-sink=file:///tmp/events.json"},
+			flagName:  "--events-sink",
+			wantRest:  []string{"--master=foo"},
+			wantValue: "file:///tmp/events.json",
+		},
+		{
+			name:      "space separated form",
+			flags:     []string{"--events-sink", "otlp://localhost:4317", "--master=foo"},
+			flagName:  "--events-sink",
+			wantRest:  []string{"--master=foo"},
+			wantValue: "otlp://localhost:4317",
+		},
+		{
+			name:      "trailing flag with no value is left alone",
+			flags:     []string{"--master=foo", "--events-sink"},
+			flagName:  "--events-sink",
+			wantRest:  []string{"--master=foo", "--events-sink"},
+			wantValue: "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gotRest, gotValue := extractFlag(c.flags, c.flagName)
+			if gotValue != c.wantValue {
+				t.Errorf("extractFlag(%v, %q) value = %q, want %q", c.flags, c.flagName, gotValue, c.wantValue)
+			}
+			if !reflect.DeepEqual(gotRest, c.wantRest) {
+				t.Errorf("extractFlag(%v, %q) rest = %v, want %v", c.flags, c.flagName, gotRest, c.wantRest)
+			}
+		})
+	}
+}
+
+func TestSetExtraEnvRestoresPriorState(t *testing.T) {
+	const (
+		preexisting = "KUBE_CONTROLLER_MANAGER_TEST_PREEXISTING"
+		unset       = "KUBE_CONTROLLER_MANAGER_TEST_UNSET"
+	)
+	os.Setenv(preexisting, "original")
+	defer os.Unsetenv(preexisting)
+	os.Unsetenv(unset)
+
+	restore, err := setExtraEnv([]string{preexisting + "=overridden", unset + "=new"})
+	if err != nil {
+		t.Fatalf("setExtraEnv: %v", err)
+	}
+	if got := os.Getenv(preexisting); got != "overridden" {
+		t.Fatalf("%s = %q after setExtraEnv, want %q", preexisting, got, "overridden")
+	}
+
+	restore()
+
+	if got := os.Getenv(preexisting); got != "original" {
+		t.Errorf("%s = %q after restore, want %q", preexisting, got, "original")
+	}
+	if _, ok := os.LookupEnv(unset); ok {
+		t.Errorf("%s still set after restore, want unset", unset)
+	}
+}